@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/gera-cl/xk6-working-memory/memory/eventbus"
+)
+
+// Backend is implemented by every cache backend driver. A Backend is free to store entries in-process, in a
+// remote store, or anywhere in between; Cache talks to whichever Backend Init selects and stays agnostic of
+// where the data actually lives. Backends need not interpret the values they are given: Cache encodes
+// every value, strings included, to msgpack bytes before calling Set (see codec.go) and decodes
+// whatever Get returns, so a Backend only has to round-trip opaque bytes faithfully.
+type Backend interface {
+	// Get retrieves the value stored under key. The boolean return reports whether the key was found.
+	Get(key string) (interface{}, bool, error)
+	// Set stores value under key with the given expiration. A zero expiration means the backend's default
+	// expiration, and a negative expiration means the entry never expires.
+	Set(key string, value interface{}, expiration time.Duration) error
+	// Delete removes key from the backend. It is not an error to delete a key that does not exist.
+	Delete(key string) error
+	// Flush removes every entry from the backend.
+	Flush() error
+	// Increment adds delta to the integer value stored under key and returns the resulting value. If key does
+	// not exist it is created with a value of delta.
+	Increment(key string, delta int64) (int64, error)
+	// TTL returns the remaining time-to-live for key. The boolean reports whether the key exists.
+	TTL(key string) (time.Duration, bool, error)
+}
+
+// DriverFactory builds a Backend from a connection URL/options string. Drivers register a DriverFactory under a
+// name via RegisterDriver so Cache.Init can look it up without the memory package importing the driver package
+// directly (which would create an import cycle, since drivers import memory to implement Backend).
+type DriverFactory func(url string) (Backend, error)
+
+// driverRegistry holds every driver registered via RegisterDriver, keyed by driver name.
+var driverRegistry = map[string]DriverFactory{}
+
+// RegisterDriver makes a cache backend driver available under name. It is intended to be called from the init
+// function of a driver subpackage (see memory/redis and memory/memcache), mirroring how database/sql drivers
+// register themselves.
+// The "memory" driver is handled directly by Cache.Init rather than through this registry, since it is the
+// default and needs no external connection.
+func RegisterDriver(name string, factory DriverFactory) {
+	driverRegistry[name] = factory
+}
+
+// StatsProvider is implemented by backends that track their own usage statistics. Cache.Stats returns an
+// error for a backend (like the default unbounded "memory" driver) that doesn't implement it.
+type StatsProvider interface {
+	Stats() BackendStats
+}
+
+// EventBusFactory builds an eventbus.Bus from a connection URL/options string.
+type EventBusFactory func(url string) (eventbus.Bus, error)
+
+// eventBusRegistry holds every event bus factory registered via RegisterEventBus, keyed by driver name.
+var eventBusRegistry = map[string]EventBusFactory{}
+
+// RegisterEventBus makes a remote pub/sub transport available under name, for use by a "layered" Cache to
+// propagate invalidations across processes. It is intended to be called from the init function of a driver
+// subpackage alongside RegisterDriver (see memory/redis).
+func RegisterEventBus(name string, factory EventBusFactory) {
+	eventBusRegistry[name] = factory
+}
@@ -0,0 +1,39 @@
+package memory
+
+import "github.com/vmihailenco/msgpack/v5"
+
+// encodeValue serializes a JS value handed to Set/SetMulti into the msgpack-encoded bytes that Backend
+// implementations actually store, so objects, arrays, numbers, and booleans all cross transparently to remote
+// backends that only understand bytes/strings - not just the plain strings the cache originally supported.
+func encodeValue(value interface{}) ([]byte, error) {
+	return msgpack.Marshal(value)
+}
+
+// decodeValue deserializes what a Backend returned from Get back into its original JS type. raw is whatever
+// the backend gave back for a previously-encoded value: []byte for most backends, or string for ones (like
+// go-redis) that hand back bulk replies as strings.
+func decodeValue(raw interface{}) (interface{}, error) {
+	data, ok := rawToBytes(raw)
+	if !ok {
+		// Not byte-shaped; the backend is handing back a value it never ran through encodeValue (e.g. an
+		// Increment result), so return it as-is.
+		return raw, nil
+	}
+	var value interface{}
+	if err := msgpack.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// rawToBytes normalizes the handful of shapes a Backend.Get can hand back for an encoded value.
+func rawToBytes(raw interface{}) ([]byte, bool) {
+	switch v := raw.(type) {
+	case []byte:
+		return v, true
+	case string:
+		return []byte(v), true
+	default:
+		return nil, false
+	}
+}
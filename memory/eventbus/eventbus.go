@@ -0,0 +1,33 @@
+/*
+Package eventbus abstracts the publish/subscribe channel that memory.LayeredCache uses to propagate cache
+invalidations: when a VU calls Set or Delete, it publishes the affected key so that every other process sharing
+the same remote backend evicts its local copy before its next Get. NewLocal is a no-op Bus for the common
+single-node case; the memory/redis package provides a Redis-backed Bus for multi-node runs.
+*/
+package eventbus
+
+// Bus is implemented by every pub/sub transport. Subscribers registered on the same channel, including ones in
+// other processes, are notified of every Publish on that channel.
+type Bus interface {
+	// Publish broadcasts message to every current subscriber of channel.
+	Publish(channel, message string) error
+	// Subscribe registers handler to be called with the message whenever something is Published on channel.
+	// The returned func unsubscribes and releases any resources held for the subscription.
+	Subscribe(channel string, handler func(message string)) (unsubscribe func() error, err error)
+}
+
+// local is a no-op Bus: Publish does nothing and Subscribe never fires, since a single process has nowhere
+// else to propagate an invalidation to.
+type local struct{}
+
+// NewLocal returns a Bus with no effect, for the common case of a single k6 runner where a LayeredCache's
+// local LRU and remote backend already live in the same process.
+func NewLocal() Bus {
+	return local{}
+}
+
+func (local) Publish(_, _ string) error { return nil }
+
+func (local) Subscribe(_ string, _ func(string)) (func() error, error) {
+	return func() error { return nil }, nil
+}
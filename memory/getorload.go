@@ -0,0 +1,61 @@
+package memory
+
+import "errors"
+
+// GetOrLoad returns the value cached under id, calling loaderFn to populate it on a miss. When N VUs miss the
+// same key at the same time, only one of them actually runs loaderFn - the rest block and receive its result -
+// which keeps an expensive loader (an auth-token fetch, a slow API call) from being hammered by every VU that
+// raced to populate the same key. When called from a k6 script, this is exposed via module.GetOrLoad, which
+// requires loaderFn to be a plain synchronous function - see its doc comment for why async loaders aren't
+// supported.
+// Parameters:
+// - id: Unique identifier of the cache entry.
+// - loaderFn: Invoked on a cache miss to produce the value to store and return.
+// - ttl: Expiration for the loaded value, in seconds. Zero uses the cache's default expiration.
+// Returns:
+// - The cached or freshly loaded value.
+// - An error if the cache is not initialized, loaderFn fails, or another issue occurs.
+func (c *Cache) GetOrLoad(id string, loaderFn func() (interface{}, error), ttl int) (interface{}, error) {
+	c.mutex.Lock()
+	if c.backend == nil {
+		c.mutex.Unlock()
+		return nil, errors.New("cache not initialized: please call init() first")
+	}
+	value, err := c.getLocked(id)
+	c.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if value != nil {
+		return value, nil
+	}
+
+	result, err, _ := c.loadGroup.Do(id, func() (interface{}, error) {
+		// Re-check now that we hold the single-flight slot for id: another VU may have just finished loading
+		// it between our miss above and acquiring this slot.
+		c.mutex.Lock()
+		value, err := c.getLocked(id)
+		c.mutex.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			return value, nil
+		}
+
+		loaded, err := loaderFn()
+		if err != nil {
+			return nil, err
+		}
+
+		c.mutex.Lock()
+		_, err = c.setLocked(id, loaded, secondsToDuration(ttl))
+		c.mutex.Unlock()
+
+		if err != nil {
+			return nil, err
+		}
+		return loaded, nil
+	})
+	return result, err
+}
@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/grafana/sobek"
+)
+
+func TestGetOrLoadLoadsOnMiss(t *testing.T) {
+	cache := &Cache{}
+	cache.Init(5, 10)
+
+	var calls int32
+	value, err := cache.GetOrLoad("key", func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return "loaded", nil
+	}, 0)
+	if err != nil {
+		t.Fatalf("expected GetOrLoad to succeed, got err: %v", err)
+	}
+	if value != "loaded" {
+		t.Fatalf("expected GetOrLoad to return %q, got %v", "loaded", value)
+	}
+	if calls != 1 {
+		t.Fatalf("expected loaderFn to be called once, got %d", calls)
+	}
+
+	value, err = cache.GetOrLoad("key", func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return "should not be used", nil
+	}, 0)
+	if err != nil {
+		t.Fatalf("expected GetOrLoad to succeed on hit, got err: %v", err)
+	}
+	if value != "loaded" {
+		t.Fatalf("expected GetOrLoad to return the cached value %q, got %v", "loaded", value)
+	}
+	if calls != 1 {
+		t.Fatalf("expected loaderFn not to be called again on a hit, got %d calls", calls)
+	}
+}
+
+func TestGetOrLoadCollapsesConcurrentMisses(t *testing.T) {
+	cache := &Cache{}
+	cache.Init(5, 10)
+
+	var calls int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.GetOrLoad("shared", func() (interface{}, error) {
+				atomic.AddInt32(&calls, 1)
+				return "value", nil
+			}, 0)
+			if err != nil {
+				t.Errorf("expected GetOrLoad to succeed, got err: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("expected loaderFn to be called exactly once across all VUs, got %d", calls)
+	}
+}
+
+func TestGetOrLoadPropagatesLoaderError(t *testing.T) {
+	cache := &Cache{}
+	cache.Init(5, 10)
+
+	loaderErr := errors.New("loader failed")
+	_, err := cache.GetOrLoad("key", func() (interface{}, error) {
+		return nil, loaderErr
+	}, 0)
+	if !errors.Is(err, loaderErr) {
+		t.Fatalf("expected GetOrLoad to propagate the loader error, got %v", err)
+	}
+}
+
+// sobekLoaderFn evaluates src, which must be a single JS function expression, and returns it as a sobek.Value
+// suitable for passing as module.GetOrLoad's loaderFn.
+func sobekLoaderFn(t *testing.T, src string) sobek.Value {
+	t.Helper()
+	rt := sobek.New()
+	v, err := rt.RunString(src)
+	if err != nil {
+		t.Fatalf("failed to evaluate loaderFn source: %v", err)
+	}
+	return v
+}
+
+func TestModuleGetOrLoadAcceptsSyncLoader(t *testing.T) {
+	globalCacheInstance = &Cache{}
+	globalCacheInstance.Init(5, 10)
+
+	mod := &module{}
+	value, err := mod.GetOrLoad("sync-key", sobekLoaderFn(t, "(function() { return 42; })"), 0)
+	if err != nil {
+		t.Fatalf("expected GetOrLoad to succeed for a synchronous loaderFn, got err: %v", err)
+	}
+	if fmt.Sprint(value) != "42" {
+		t.Fatalf("expected GetOrLoad to return 42, got %v (%T)", value, value)
+	}
+}
+
+func TestModuleGetOrLoadRejectsAsyncLoader(t *testing.T) {
+	globalCacheInstance = &Cache{}
+	globalCacheInstance.Init(5, 10)
+
+	mod := &module{}
+	_, err := mod.GetOrLoad("async-key", sobekLoaderFn(t, "(async function() { return 42; })"), 0)
+	if err == nil {
+		t.Fatalf("expected GetOrLoad to reject an async/Promise-returning loaderFn")
+	}
+}
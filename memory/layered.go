@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/gera-cl/xk6-working-memory/memory/eventbus"
+)
+
+// flushMessage is published on a LayeredCache's invalidation channel to tell subscribers to drop their entire
+// local LRU rather than a single key, since Flush affects every key at once.
+const flushMessage = "\x00flush"
+
+// LayeredCache is a Backend composed of a bounded in-process LRU (L1) in front of a shared remote Backend (L2,
+// typically Redis). Reads are served from the LRU when possible for sub-microsecond hits on hot keys; writes go
+// to the remote Backend first and are then published on bus so every other process sharing it evicts its own
+// L1 copy before its next Get, avoiding the stale reads a plain local cache would risk under load.
+type LayeredCache struct {
+	local   Backend
+	remote  Backend
+	bus     eventbus.Bus
+	channel string
+}
+
+// NewLayeredCache builds a LayeredCache from an already-bounded local Backend and a shared remote Backend,
+// subscribing to channel on bus to receive invalidations published by other processes.
+func NewLayeredCache(local, remote Backend, bus eventbus.Bus, channel string) (*LayeredCache, error) {
+	lc := &LayeredCache{local: local, remote: remote, bus: bus, channel: channel}
+	if _, err := bus.Subscribe(channel, lc.onInvalidate); err != nil {
+		return nil, err
+	}
+	return lc, nil
+}
+
+// onInvalidate handles a message published on lc.channel by any process, evicting the affected key (or the
+// whole local LRU, for flushMessage) from the local tier.
+func (lc *LayeredCache) onInvalidate(message string) {
+	if message == flushMessage {
+		_ = lc.local.Flush()
+		return
+	}
+	_ = lc.local.Delete(message)
+}
+
+func (lc *LayeredCache) Get(key string) (interface{}, bool, error) {
+	if value, found, err := lc.local.Get(key); err == nil && found {
+		return value, true, nil
+	}
+
+	value, found, err := lc.remote.Get(key)
+	if err != nil || !found {
+		return value, found, err
+	}
+
+	ttl, _, err := lc.remote.TTL(key)
+	if err != nil {
+		// Don't populate the local tier on a TTL round-trip failure: lruBackend treats an 0 expiration as
+		// "never expires" (see buildLayeredBackend), so caching with a guessed ttl of 0 here would pin this
+		// value in L1 forever regardless of the remote's real TTL. Serving straight from remote this once and
+		// leaving L1 unpopulated is safe - the next Get just repeats the remote round-trip.
+		return value, true, nil
+	}
+	_ = lc.local.Set(key, value, ttl)
+	return value, true, nil
+}
+
+func (lc *LayeredCache) Set(key string, value interface{}, expiration time.Duration) error {
+	if err := lc.remote.Set(key, value, expiration); err != nil {
+		return err
+	}
+	_ = lc.local.Set(key, value, expiration)
+	return lc.bus.Publish(lc.channel, key)
+}
+
+func (lc *LayeredCache) Delete(key string) error {
+	if err := lc.remote.Delete(key); err != nil {
+		return err
+	}
+	_ = lc.local.Delete(key)
+	return lc.bus.Publish(lc.channel, key)
+}
+
+func (lc *LayeredCache) Flush() error {
+	if err := lc.remote.Flush(); err != nil {
+		return err
+	}
+	_ = lc.local.Flush()
+	return lc.bus.Publish(lc.channel, flushMessage)
+}
+
+func (lc *LayeredCache) Increment(key string, delta int64) (int64, error) {
+	value, err := lc.remote.Increment(key, delta)
+	if err != nil {
+		return 0, err
+	}
+	_ = lc.local.Delete(key)
+	if pubErr := lc.bus.Publish(lc.channel, key); pubErr != nil {
+		return value, pubErr
+	}
+	return value, nil
+}
+
+func (lc *LayeredCache) TTL(key string) (time.Duration, bool, error) {
+	return lc.remote.TTL(key)
+}
+
+// Stats implements StatsProvider by reporting the local L1 LRU's statistics - hit rate there is what a
+// LayeredCache actually exists to improve, since every L1 miss still has to round-trip to the remote backend.
+func (lc *LayeredCache) Stats() BackendStats {
+	if provider, ok := lc.local.(StatsProvider); ok {
+		return provider.Stats()
+	}
+	return BackendStats{}
+}
@@ -0,0 +1,202 @@
+package memory
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gera-cl/xk6-working-memory/memory/eventbus"
+)
+
+// fakeBus is an in-memory eventbus.Bus that actually delivers each Publish to every handler Subscribed on the
+// same channel, unlike eventbus.NewLocal's no-op. It stands in for a real Redis-backed Bus so tests can
+// exercise cross-process invalidation between two independent LayeredCache instances sharing one remote.
+type fakeBus struct {
+	mu       sync.Mutex
+	handlers map[string][]func(string)
+}
+
+func newFakeBus() *fakeBus {
+	return &fakeBus{handlers: make(map[string][]func(string))}
+}
+
+func (b *fakeBus) Publish(channel, message string) error {
+	b.mu.Lock()
+	handlers := append([]func(string){}, b.handlers[channel]...)
+	b.mu.Unlock()
+	for _, handler := range handlers {
+		handler(message)
+	}
+	return nil
+}
+
+func (b *fakeBus) Subscribe(channel string, handler func(message string)) (func() error, error) {
+	b.mu.Lock()
+	b.handlers[channel] = append(b.handlers[channel], handler)
+	b.mu.Unlock()
+	return func() error { return nil }, nil
+}
+
+func newTestLayeredCache(t *testing.T) *LayeredCache {
+	t.Helper()
+	local, err := newLRUBackend(10, 0, 0)
+	if err != nil {
+		t.Fatalf("expected newLRUBackend to succeed, got err: %v", err)
+	}
+	remote := newMemoryBackendFromDurations(0, 0)
+	lc, err := NewLayeredCache(local, remote, eventbus.NewLocal(), "test-channel")
+	if err != nil {
+		t.Fatalf("expected NewLayeredCache to succeed, got err: %v", err)
+	}
+	return lc
+}
+
+func TestLayeredCacheSetAndGet(t *testing.T) {
+	lc := newTestLayeredCache(t)
+
+	if err := lc.Set("key", "value", 0); err != nil {
+		t.Fatalf("expected Set to succeed, got err: %v", err)
+	}
+
+	value, found, err := lc.Get("key")
+	if err != nil || !found {
+		t.Fatalf("expected Get to find the key, got found=%v err=%v", found, err)
+	}
+	if value != "value" {
+		t.Fatalf("expected Get to return %q, got %v", "value", value)
+	}
+}
+
+func TestLayeredCacheGetFallsBackToRemote(t *testing.T) {
+	lc := newTestLayeredCache(t)
+
+	if err := lc.remote.Set("key", "value", 0); err != nil {
+		t.Fatalf("expected remote Set to succeed, got err: %v", err)
+	}
+
+	value, found, err := lc.Get("key")
+	if err != nil || !found {
+		t.Fatalf("expected Get to find the key via remote, got found=%v err=%v", found, err)
+	}
+	if value != "value" {
+		t.Fatalf("expected Get to return %q, got %v", "value", value)
+	}
+
+	if _, found, _ := lc.local.Get("key"); !found {
+		t.Fatalf("expected the remote hit to populate the local LRU")
+	}
+}
+
+func TestLayeredCacheDelete(t *testing.T) {
+	lc := newTestLayeredCache(t)
+
+	_ = lc.Set("key", "value", 0)
+	if err := lc.Delete("key"); err != nil {
+		t.Fatalf("expected Delete to succeed, got err: %v", err)
+	}
+
+	if _, found, _ := lc.Get("key"); found {
+		t.Fatalf("expected key to be gone after Delete")
+	}
+}
+
+func TestLayeredCacheInvalidationPropagatesAcrossInstances(t *testing.T) {
+	bus := newFakeBus()
+	remote := newMemoryBackendFromDurations(0, 0)
+
+	local1, err := newLRUBackend(10, 0, 0)
+	if err != nil {
+		t.Fatalf("expected newLRUBackend to succeed, got err: %v", err)
+	}
+	lc1, err := NewLayeredCache(local1, remote, bus, "shared-channel")
+	if err != nil {
+		t.Fatalf("expected NewLayeredCache to succeed, got err: %v", err)
+	}
+
+	local2, err := newLRUBackend(10, 0, 0)
+	if err != nil {
+		t.Fatalf("expected newLRUBackend to succeed, got err: %v", err)
+	}
+	lc2, err := NewLayeredCache(local2, remote, bus, "shared-channel")
+	if err != nil {
+		t.Fatalf("expected NewLayeredCache to succeed, got err: %v", err)
+	}
+
+	if err := lc1.Set("key", "value", 0); err != nil {
+		t.Fatalf("expected lc1.Set to succeed, got err: %v", err)
+	}
+
+	if _, found, _ := lc2.Get("key"); !found {
+		t.Fatalf("expected lc2.Get to find the key via remote")
+	}
+	if _, found, _ := lc2.local.Get("key"); !found {
+		t.Fatalf("expected lc2's remote hit to populate its own local LRU")
+	}
+
+	if err := lc1.Set("key", "updated", 0); err != nil {
+		t.Fatalf("expected lc1.Set to succeed, got err: %v", err)
+	}
+
+	if _, found, _ := lc2.local.Get("key"); found {
+		t.Fatalf("expected lc1's Set to invalidate lc2's local LRU via the shared bus")
+	}
+
+	value, found, err := lc2.Get("key")
+	if err != nil || !found {
+		t.Fatalf("expected lc2.Get to find the updated key via remote, got found=%v err=%v", found, err)
+	}
+	if value != "updated" {
+		t.Fatalf("expected lc2.Get to return %q, got %v", "updated", value)
+	}
+}
+
+func TestLayeredCacheGetDoesNotCacheLocallyOnTTLError(t *testing.T) {
+	lc := newTestLayeredCache(t)
+
+	if err := lc.remote.Set("key", "value", 0); err != nil {
+		t.Fatalf("expected remote Set to succeed, got err: %v", err)
+	}
+	// memoryBackend's TTL never returns an error; swap it for one that always fails, simulating a transient
+	// remote error, to verify Get doesn't then cache "value" as non-expiring.
+	lc.remote = failingTTLBackend{Backend: lc.remote}
+
+	value, found, err := lc.Get("key")
+	if err != nil || !found {
+		t.Fatalf("expected Get to still find the key despite the TTL error, got found=%v err=%v", found, err)
+	}
+	if value != "value" {
+		t.Fatalf("expected Get to return %q, got %v", "value", value)
+	}
+
+	if _, found, _ := lc.local.Get("key"); found {
+		t.Fatalf("expected Get not to populate the local LRU when the TTL lookup failed")
+	}
+}
+
+// failingTTLBackend wraps a Backend to make TTL always fail, simulating a transient remote error.
+type failingTTLBackend struct {
+	Backend
+}
+
+func (failingTTLBackend) TTL(string) (time.Duration, bool, error) {
+	return 0, false, errors.New("simulated TTL failure")
+}
+
+func TestLayeredCacheFlush(t *testing.T) {
+	lc := newTestLayeredCache(t)
+
+	_ = lc.Set("key1", "value1", 0)
+	_ = lc.Set("key2", "value2", 0)
+
+	if err := lc.Flush(); err != nil {
+		t.Fatalf("expected Flush to succeed, got err: %v", err)
+	}
+
+	if _, found, _ := lc.Get("key1"); found {
+		t.Fatalf("expected key1 to be gone after Flush")
+	}
+	if _, found, _ := lc.Get("key2"); found {
+		t.Fatalf("expected key2 to be gone after Flush")
+	}
+}
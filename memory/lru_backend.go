@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// lruEntry pairs a stored value with its absolute expiration and estimated size (see entrySize), mirroring how memoryBackend
+// tracks expirations through go-cache but atop a size-bounded github.com/hashicorp/golang-lru/v2 cache instead
+// of an unbounded map.
+type lruEntry struct {
+	value     interface{}
+	expiresAt time.Time // zero value means "never expires"
+	bytes     int64
+}
+
+// lruBackend is a Backend bounded both by entry count (maxEntries, enforced by the underlying LRU cache) and
+// by total value size (maxBytes, enforced by evicting the least recently used entries after every Set), so a
+// long soak test's cache can't grow without bound. It tracks hit/miss/eviction counts for Cache.Stats, and
+// backs the local L1 tier of a LayeredCache.
+type lruBackend struct {
+	cache             *lru.Cache[string, lruEntry]
+	defaultExpiration time.Duration
+	maxBytes          int64
+
+	bytesUsed int64 // atomic
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// newLRUBackend builds an lruBackend holding at most maxEntries items and, if maxBytes > 0, no more than
+// maxBytes of total value size. defaultExpiration applies when Set is called with expiration == 0.
+func newLRUBackend(maxEntries int, maxBytes int64, defaultExpiration time.Duration) (*lruBackend, error) {
+	b := &lruBackend{defaultExpiration: defaultExpiration, maxBytes: maxBytes}
+	cache, err := lru.NewWithEvict[string, lruEntry](maxEntries, b.onEvict)
+	if err != nil {
+		return nil, err
+	}
+	b.cache = cache
+	return b, nil
+}
+
+// onEvict is called by the underlying LRU cache whenever it drops an entry: because it hit maxEntries, because
+// Set asked it to RemoveOldest to stay under maxBytes, or because of a Remove (Delete, or Get finding the entry
+// expired) or a Purge (Flush). Every one of these counts toward evictions.
+func (b *lruBackend) onEvict(_ string, entry lruEntry) {
+	atomic.AddInt64(&b.bytesUsed, -entry.bytes)
+	atomic.AddUint64(&b.evictions, 1)
+}
+
+func (b *lruBackend) Get(key string) (interface{}, bool, error) {
+	entry, found := b.cache.Get(key)
+	if !found {
+		atomic.AddUint64(&b.misses, 1)
+		return nil, false, nil
+	}
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		b.cache.Remove(key)
+		atomic.AddUint64(&b.misses, 1)
+		return nil, false, nil
+	}
+	atomic.AddUint64(&b.hits, 1)
+	return entry.value, true, nil
+}
+
+func (b *lruBackend) Set(key string, value interface{}, expiration time.Duration) error {
+	if expiration == 0 {
+		expiration = b.defaultExpiration
+	}
+	var expiresAt time.Time
+	if expiration > 0 {
+		expiresAt = time.Now().Add(expiration)
+	}
+	entry := lruEntry{value: value, expiresAt: expiresAt, bytes: entrySize(key, value)}
+	if old, found := b.cache.Peek(key); found {
+		// Add replaces an existing entry without calling onEvict, so release its bytes here.
+		atomic.AddInt64(&b.bytesUsed, -old.bytes)
+	}
+	atomic.AddInt64(&b.bytesUsed, entry.bytes)
+	b.cache.Add(key, entry)
+	b.enforceMaxBytes()
+	return nil
+}
+
+// enforceMaxBytes evicts least-recently-used entries until total value size is back under maxBytes. It is a
+// no-op when maxBytes is 0 (unbounded).
+func (b *lruBackend) enforceMaxBytes() {
+	if b.maxBytes <= 0 {
+		return
+	}
+	for atomic.LoadInt64(&b.bytesUsed) > b.maxBytes {
+		if _, _, evicted := b.cache.RemoveOldest(); !evicted {
+			return
+		}
+	}
+}
+
+// entrySize estimates how many bytes value occupies for maxBytes accounting. Cache always hands backends
+// already-encoded []byte or string values (see codec.go); other types fall back to a small fixed estimate
+// rather than a backend needing to know how to size arbitrary Go values.
+func entrySize(key string, value interface{}) int64 {
+	size := int64(len(key))
+	switch v := value.(type) {
+	case []byte:
+		size += int64(len(v))
+	case string:
+		size += int64(len(v))
+	default:
+		size += 8
+	}
+	return size
+}
+
+func (b *lruBackend) Delete(key string) error {
+	b.cache.Remove(key)
+	return nil
+}
+
+func (b *lruBackend) Flush() error {
+	b.cache.Purge()
+	atomic.StoreInt64(&b.bytesUsed, 0)
+	return nil
+}
+
+func (b *lruBackend) Increment(key string, delta int64) (int64, error) {
+	entry, found := b.cache.Get(key)
+	var current int64
+	if found {
+		atomic.AddInt64(&b.bytesUsed, -entry.bytes)
+		if n, ok := entry.value.(int64); ok {
+			current = n
+		}
+	}
+	current += delta
+	entry.value = current
+	entry.bytes = entrySize(key, current)
+	atomic.AddInt64(&b.bytesUsed, entry.bytes)
+	b.cache.Add(key, entry)
+	b.enforceMaxBytes()
+	return current, nil
+}
+
+func (b *lruBackend) TTL(key string) (time.Duration, bool, error) {
+	entry, found := b.cache.Get(key)
+	if !found {
+		return 0, false, nil
+	}
+	if entry.expiresAt.IsZero() {
+		return -1, true, nil
+	}
+	return time.Until(entry.expiresAt), true, nil
+}
+
+// BackendStats reports usage statistics for backends that track them, see StatsProvider.
+type BackendStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+	Bytes     int64
+}
+
+// Stats implements StatsProvider.
+func (b *lruBackend) Stats() BackendStats {
+	return BackendStats{
+		Hits:      atomic.LoadUint64(&b.hits),
+		Misses:    atomic.LoadUint64(&b.misses),
+		Evictions: atomic.LoadUint64(&b.evictions),
+		Size:      b.cache.Len(),
+		Bytes:     atomic.LoadInt64(&b.bytesUsed),
+	}
+}
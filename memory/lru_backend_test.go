@@ -0,0 +1,70 @@
+package memory
+
+import "testing"
+
+func TestLRUBackendEvictsOverMaxEntries(t *testing.T) {
+	b, err := newLRUBackend(2, 0, 0)
+	if err != nil {
+		t.Fatalf("expected newLRUBackend to succeed, got err: %v", err)
+	}
+
+	_ = b.Set("a", "1", 0)
+	_ = b.Set("b", "2", 0)
+	_ = b.Set("c", "3", 0) // evicts "a", the least recently used
+
+	if _, found, _ := b.Get("a"); found {
+		t.Fatalf("expected %q to have been evicted", "a")
+	}
+	if _, found, _ := b.Get("c"); !found {
+		t.Fatalf("expected %q to still be present", "c")
+	}
+
+	stats := b.Stats()
+	if stats.Evictions != 1 {
+		t.Fatalf("expected 1 eviction, got %d", stats.Evictions)
+	}
+	if stats.Size != 2 {
+		t.Fatalf("expected size 2, got %d", stats.Size)
+	}
+}
+
+func TestLRUBackendEvictsOverMaxBytes(t *testing.T) {
+	b, err := newLRUBackend(10, 10, 0)
+	if err != nil {
+		t.Fatalf("expected newLRUBackend to succeed, got err: %v", err)
+	}
+
+	_ = b.Set("a", []byte("12345"), 0)
+	_ = b.Set("b", []byte("12345"), 0) // pushes bytesUsed over 10, evicting "a"
+
+	if _, found, _ := b.Get("a"); found {
+		t.Fatalf("expected %q to have been evicted to stay under maxBytes", "a")
+	}
+
+	stats := b.Stats()
+	if stats.Evictions == 0 {
+		t.Fatalf("expected at least one eviction")
+	}
+	if stats.Bytes > 10 {
+		t.Fatalf("expected bytes used to stay at or under maxBytes, got %d", stats.Bytes)
+	}
+}
+
+func TestLRUBackendTracksHitsAndMisses(t *testing.T) {
+	b, err := newLRUBackend(10, 0, 0)
+	if err != nil {
+		t.Fatalf("expected newLRUBackend to succeed, got err: %v", err)
+	}
+
+	_ = b.Set("key", "value", 0)
+	_, _, _ = b.Get("key")     // hit
+	_, _, _ = b.Get("missing") // miss
+
+	stats := b.Stats()
+	if stats.Hits != 1 {
+		t.Fatalf("expected 1 hit, got %d", stats.Hits)
+	}
+	if stats.Misses != 1 {
+		t.Fatalf("expected 1 miss, got %d", stats.Misses)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Package memcache registers the "memcache" cache backend driver for use with memory.Cache.Init, so k6 tests
+running in distributed mode across multiple runners can share cache state through Memcached instead of the
+in-process "memory" driver, which only works within a single process.
+
+Import it for its side effect, alongside the memory module itself:
+
+	import _ "github.com/gera-cl/xk6-working-memory/memory/memcache"
+*/
+package memcache
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+
+	"github.com/gera-cl/xk6-working-memory/memory"
+)
+
+func init() {
+	memory.RegisterDriver("memcache", New)
+}
+
+// backend implements memory.Backend on top of a gomemcache client. Memcached has no native TTL-query command,
+// so TTL tracks expirations itself in parallel with what it sends to the server.
+type backend struct {
+	client      *memcache.Client
+	expirations sync.Map // key (string) -> expiration time.Time, zero value means "never expires"
+}
+
+// New connects to the Memcached server(s) described by url, a comma-separated list of "host:port" addresses,
+// and returns a memory.Backend backed by them.
+func New(url string) (memory.Backend, error) {
+	if url == "" {
+		return nil, errors.New("memcache: url must list at least one host:port address")
+	}
+	addrs := strings.Split(url, ",")
+	for i := range addrs {
+		addrs[i] = strings.TrimSpace(addrs[i])
+	}
+	return &backend{client: memcache.New(addrs...)}, nil
+}
+
+// toBytes normalizes the shapes memory.Cache's encode step can hand a Backend: []byte for msgpack-encoded
+// values, or a plain string for callers using the Backend directly without going through Cache.
+func toBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, errors.New("memcache: value must be []byte or string")
+	}
+}
+
+func (b *backend) Get(key string) (interface{}, bool, error) {
+	item, err := b.client.Get(key)
+	if err == memcache.ErrCacheMiss {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return item.Value, true, nil
+}
+
+func (b *backend) Set(key string, value interface{}, expiration time.Duration) error {
+	data, err := toBytes(value)
+	if err != nil {
+		return err
+	}
+	// Per the Backend.Set contract, a negative expiration means "never expires" - but memcached's protocol
+	// treats a negative exptime as "invalidate immediately", the opposite meaning. Clamp to 0 (memcached's own
+	// "never expires") so a negative expiration behaves the same here as it does on every other driver.
+	exptime := expiration
+	if exptime < 0 {
+		exptime = 0
+	}
+	err = b.client.Set(&memcache.Item{Key: key, Value: data, Expiration: int32(exptime.Seconds())})
+	if err != nil {
+		return err
+	}
+	if expiration <= 0 {
+		b.expirations.Delete(key)
+	} else {
+		b.expirations.Store(key, time.Now().Add(expiration))
+	}
+	return nil
+}
+
+func (b *backend) Delete(key string) error {
+	b.expirations.Delete(key)
+	err := b.client.Delete(key)
+	if err == memcache.ErrCacheMiss {
+		return nil
+	}
+	return err
+}
+
+func (b *backend) Flush() error {
+	b.expirations.Range(func(key, _ interface{}) bool {
+		b.expirations.Delete(key)
+		return true
+	})
+	return b.client.FlushAll()
+}
+
+func (b *backend) Increment(key string, delta int64) (int64, error) {
+	var newValue uint64
+	var err error
+	if delta >= 0 {
+		newValue, err = b.client.Increment(key, uint64(delta))
+	} else {
+		newValue, err = b.client.Decrement(key, uint64(-delta))
+	}
+	if err == memcache.ErrCacheMiss {
+		if setErr := b.client.Set(&memcache.Item{Key: key, Value: []byte(strconv.FormatInt(delta, 10))}); setErr != nil {
+			return 0, setErr
+		}
+		return delta, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int64(newValue), nil
+}
+
+func (b *backend) TTL(key string) (time.Duration, bool, error) {
+	_, found, err := b.Get(key)
+	if err != nil || !found {
+		return 0, false, err
+	}
+	expiresAt, hasExpiration := b.expirations.Load(key)
+	if !hasExpiration {
+		return -1, true, nil
+	}
+	return time.Until(expiresAt.(time.Time)), true, nil
+}
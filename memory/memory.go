@@ -1,22 +1,24 @@
 /*
-Package memory provides a caching module for use with k6, an open-source load testing tool. This package uses
-an in-memory cache with configurable expiration and cleanup intervals, allowing for temporary data storage across
-virtual users (VUs).
+Package memory provides a caching module for use with k6, an open-source load testing tool. Cache delegates
+storage to a pluggable Backend (see backend.go): the default "memory" driver keeps entries in-process via
+github.com/patrickmn/go-cache, while the memory/redis and memory/memcache subpackages register drivers that
+let VUs share state across runners in distributed k6 runs.
 
 Import Path:
 	const ImportPath = "k6/x/working-memory"
-
-Package memory utilizes github.com/patrickmn/go-cache for cache management, allowing for item expiration and cleanup.
 */
 
 package memory
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
-	"github.com/patrickmn/go-cache"
+	"github.com/grafana/sobek"
+	"golang.org/x/sync/singleflight"
+
 	"go.k6.io/k6/js/modules"
 )
 
@@ -28,23 +30,271 @@ var (
 	once                sync.Once
 )
 
-// Cache struct encapsulates an in-memory cache with a mutex for concurrent access management.
+// Cache wraps a Backend with a mutex for concurrent access management, so a single Cache can be shared safely
+// across VUs within a process. loadGroup collapses concurrent GetOrLoad misses on the same key into a single
+// loaderFn call. metricsMu/lastHits/lastMisses/lastEvicts track the last-reported totals for emitMetricsDelta,
+// so every VU's Stats() call cooperates on one true delta stream instead of each computing its own from zero.
 type Cache struct {
-	cache *cache.Cache
-	mutex sync.Mutex
+	backend   Backend
+	mutex     sync.Mutex
+	loadGroup singleflight.Group
+
+	metricsMu                        sync.Mutex
+	lastHits, lastMisses, lastEvicts uint64
+}
+
+// metricsDelta returns how much stats' cumulative hits/misses/evictions have grown since the last call across
+// all VUs, and records stats as the new baseline. Because BackendStats is cumulative but the emitted metrics
+// are k6 Counters (which sum every sample pushed), this must be computed from one shared baseline rather than
+// one per VU, or each VU's first call would double-count every hit/miss/eviction that happened before it.
+func (c *Cache) metricsDelta(stats BackendStats) (hits, misses, evictions uint64) {
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	hits = deltaUint64(stats.Hits, c.lastHits)
+	misses = deltaUint64(stats.Misses, c.lastMisses)
+	evictions = deltaUint64(stats.Evictions, c.lastEvicts)
+	c.lastHits, c.lastMisses, c.lastEvicts = stats.Hits, stats.Misses, stats.Evictions
+	return hits, misses, evictions
+}
+
+// Init initializes the Cache, selecting and configuring a Backend driver.
+//
+// Two call forms are supported:
+//   - init(defaultExpiration, cleanupInterval int): the legacy form, a shortcut for the in-process "memory"
+//     driver. Both arguments are in seconds.
+//   - init({driver: "redis", url: "redis://...", defaultExpiration: 5, cleanupInterval: 10}): selects a
+//     registered driver by name, passing it the url/options to connect with. defaultExpiration and
+//     cleanupInterval are in seconds and only apply to the "memory" driver.
+func (c *Cache) Init(args ...interface{}) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	backend, err := buildBackend(args)
+	if err != nil {
+		return err
+	}
+	c.backend = backend
+	return nil
 }
 
-// Init initializes the Cache with a default expiration time and cleanup interval.
+// InitPersistent initializes the Cache like Init does, but mirrors every write to disk under dir so entries
+// survive between k6 runs - useful for expensive setup data like generated JWTs, seeded test fixtures, or
+// downloaded artifacts. Entries whose stored expiration is still in the future are reloaded into memory
+// immediately. See PersistentCacheVersion to invalidate the entire on-disk cache at once.
 // Parameters:
+// - dir: Directory to store cache entries under. Created if it does not already exist.
 // - defaultExpiration: Cache expiration time in seconds.
-// - cleanupInterval: Interval in seconds at which expired items are removed from the cache.
-func (c *Cache) Init(defaultExpiration, cleanupInterval int) {
+// - cleanupInterval: Interval in seconds at which expired items are removed from the in-memory cache.
+func (c *Cache) InitPersistent(dir string, defaultExpiration, cleanupInterval int) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	expiration := time.Duration(defaultExpiration) * time.Second
-	cleanup := time.Duration(cleanupInterval) * time.Second
-	c.cache = cache.New(expiration, cleanup)
+	local := newMemoryBackendFromDurations(secondsToDuration(defaultExpiration), secondsToDuration(cleanupInterval))
+	backend, err := newPersistentBackend(dir, local)
+	if err != nil {
+		return err
+	}
+	if err := backend.loadFromDisk(); err != nil {
+		return err
+	}
+	c.backend = backend
+	return nil
+}
+
+// buildBackend resolves the arguments passed to Init into a configured Backend, dispatching on the legacy
+// two-int form versus the newer options-object form.
+func buildBackend(args []interface{}) (Backend, error) {
+	if defaultExpiration, cleanupInterval, ok := legacyInitArgs(args); ok {
+		return newMemoryBackendFromDurations(
+			time.Duration(defaultExpiration)*time.Second,
+			time.Duration(cleanupInterval)*time.Second,
+		), nil
+	}
+
+	opts, err := parseInitOptions(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Driver == "memory" || opts.Driver == "" {
+		defaultExpiration := time.Duration(opts.DefaultExpiration) * time.Second
+		if opts.MaxEntries > 0 || opts.MaxBytes > 0 {
+			maxEntries := opts.MaxEntries
+			if maxEntries <= 0 {
+				maxEntries = defaultLRUMaxEntries
+			}
+			return newLRUBackend(maxEntries, opts.MaxBytes, defaultExpiration)
+		}
+		return newMemoryBackendFromDurations(
+			defaultExpiration,
+			time.Duration(opts.CleanupInterval)*time.Second,
+		), nil
+	}
+
+	if opts.Driver == "layered" {
+		return buildLayeredBackend(opts)
+	}
+
+	factory, ok := driverRegistry[opts.Driver]
+	if !ok {
+		return nil, fmt.Errorf("memory: unknown driver %q, did you import its package?", opts.Driver)
+	}
+	return factory(opts.URL)
+}
+
+// buildLayeredBackend builds a LayeredCache from an options object selecting driver "layered": a bounded local
+// LRU in front of opts.RemoteDriver (e.g. "redis"), invalidated across processes over the matching registered
+// EventBusFactory.
+func buildLayeredBackend(opts initOptions) (Backend, error) {
+	remoteFactory, ok := driverRegistry[opts.RemoteDriver]
+	if !ok {
+		return nil, fmt.Errorf("memory: unknown remoteDriver %q, did you import its package?", opts.RemoteDriver)
+	}
+	remote, err := remoteFactory(opts.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	busFactory, ok := eventBusRegistry[opts.RemoteDriver]
+	if !ok {
+		return nil, fmt.Errorf("memory: remoteDriver %q has no registered event bus", opts.RemoteDriver)
+	}
+	bus, err := busFactory(opts.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	maxEntries := opts.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultLayeredMaxEntries
+	}
+	local, err := newLRUBackend(maxEntries, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	channel := opts.Channel
+	if channel == "" {
+		channel = defaultLayeredChannel
+	}
+	return NewLayeredCache(local, remote, bus, channel)
+}
+
+// defaultLayeredMaxEntries bounds a layered cache's local LRU when the caller does not specify maxEntries.
+const defaultLayeredMaxEntries = 10000
+
+// defaultLRUMaxEntries bounds the default driver's LRU when the caller sets maxBytes but not maxEntries.
+const defaultLRUMaxEntries = 1 << 20
+
+// defaultLayeredChannel is the pub/sub channel a layered cache uses when the caller does not specify one.
+const defaultLayeredChannel = "xk6-working-memory:invalidate"
+
+// legacyInitArgs recognizes the original init(defaultExpiration, cleanupInterval int) call form.
+func legacyInitArgs(args []interface{}) (defaultExpiration, cleanupInterval int, ok bool) {
+	if len(args) != 2 {
+		return 0, 0, false
+	}
+	a, aok := toInt(args[0])
+	b, bok := toInt(args[1])
+	if !aok || !bok {
+		return 0, 0, false
+	}
+	return a, b, true
+}
+
+// initOptions configures which backend driver a Cache uses and how it behaves.
+type initOptions struct {
+	Driver            string
+	URL               string
+	DefaultExpiration int
+	CleanupInterval   int
+
+	// MaxEntries and MaxBytes bound the "memory" driver's LRU: MaxEntries caps the number of entries and
+	// MaxBytes caps the total size of their values. Either may be set alone; if both are zero the driver falls
+	// back to the original unbounded go-cache backend. MaxEntries also bounds the "layered" driver's local L1.
+	MaxEntries int
+	MaxBytes   int64
+
+	// RemoteDriver and Channel only apply to the "layered" driver: RemoteDriver names the registered
+	// Backend/EventBus driver used as the remote L2 tier (e.g. "redis"), and Channel is the pub/sub channel
+	// invalidations are published on.
+	RemoteDriver string
+	Channel      string
+}
+
+// parseInitOptions reads the init({driver, url, defaultExpiration, cleanupInterval, ...}) call form.
+func parseInitOptions(args []interface{}) (initOptions, error) {
+	if len(args) != 1 {
+		return initOptions{}, errors.New("memory: init() expects either (defaultExpiration, cleanupInterval) or a single options object")
+	}
+	raw, ok := args[0].(map[string]interface{})
+	if !ok {
+		return initOptions{}, errors.New("memory: init() options must be an object")
+	}
+
+	opts := initOptions{Driver: "memory"}
+	if driver, ok := raw["driver"].(string); ok {
+		opts.Driver = driver
+	}
+	if url, ok := raw["url"].(string); ok {
+		opts.URL = url
+	}
+	if v, ok := toInt(raw["defaultExpiration"]); ok {
+		opts.DefaultExpiration = v
+	}
+	if v, ok := toInt(raw["cleanupInterval"]); ok {
+		opts.CleanupInterval = v
+	}
+	if remoteDriver, ok := raw["remoteDriver"].(string); ok {
+		opts.RemoteDriver = remoteDriver
+	} else {
+		opts.RemoteDriver = "redis"
+	}
+	if v, ok := toInt(raw["maxEntries"]); ok {
+		opts.MaxEntries = v
+	}
+	if v, ok := toInt64(raw["maxBytes"]); ok {
+		opts.MaxBytes = v
+	}
+	if channel, ok := raw["channel"].(string); ok {
+		opts.Channel = channel
+	}
+	return opts, nil
+}
+
+// secondsToDuration converts a seconds count, as taken by Init/Set/GetOrLoad's expiration parameters, to a
+// time.Duration.
+func secondsToDuration(seconds int) time.Duration {
+	return time.Duration(seconds) * time.Second
+}
+
+// toInt converts the numeric types sobek typically hands Go functions (int, int64, float64) into an int.
+func toInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	default:
+		return 0, false
+	}
+}
+
+// toInt64 is toInt for fields like maxBytes that can exceed the int range on 32-bit platforms.
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), true
+	default:
+		return 0, false
+	}
 }
 
 // New creates and returns a new instance of rootModule, implementing the k6 modules.Module interface.
@@ -59,23 +309,36 @@ func New() modules.Module {
 type rootModule struct{}
 
 // NewModuleInstance creates and returns a new module instance for each VU.
-func (*rootModule) NewModuleInstance(_ modules.VU) modules.Instance {
-	instance := &module{
-		exports: modules.Exports{
-			Default: globalCacheInstance,
-			Named: map[string]interface{}{
-				"init":  globalCacheInstance.Init,
-				"set":   globalCacheInstance.Set,
-				"get":   globalCacheInstance.Get,
-				"flush": globalCacheInstance.Flush,
-			},
+func (*rootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	instance := &module{vu: vu}
+	cacheMetrics, err := registerCacheMetrics(vu)
+	if err == nil {
+		instance.metrics = cacheMetrics
+	}
+
+	instance.exports = modules.Exports{
+		Default: globalCacheInstance,
+		Named: map[string]interface{}{
+			"init":           globalCacheInstance.Init,
+			"initPersistent": globalCacheInstance.InitPersistent,
+			"set":            globalCacheInstance.Set,
+			"get":            globalCacheInstance.Get,
+			"getMulti":       globalCacheInstance.GetMulti,
+			"setMulti":       globalCacheInstance.SetMulti,
+			"getOrLoad":      instance.GetOrLoad,
+			"flush":          globalCacheInstance.Flush,
+			"stats":          instance.Stats,
 		},
 	}
 	return instance
 }
 
-// module defines a structure with exported functions for use in k6 scripts.
+// module defines a structure with exported functions for use in k6 scripts. Unlike Cache's methods, which are
+// shared process-wide through globalCacheInstance, module is created fresh per VU so Stats can emit metrics
+// tagged for that VU's current iteration.
 type module struct {
+	vu      modules.VU
+	metrics *cacheMetrics
 	exports modules.Exports
 }
 
@@ -84,7 +347,52 @@ func (mod *module) Exports() modules.Exports {
 	return mod.exports
 }
 
-// Set stores a value in the cache under the specified id with an optional expiration.
+// Stats is the JS-facing wrapper around Cache.Stats that also emits the cache's hit/miss/eviction/size/bytes
+// counts as k6 custom metrics, so they show up alongside HTTP timings in k6's own output.
+func (mod *module) Stats() (map[string]interface{}, error) {
+	stats, err := globalCacheInstance.Stats()
+	if err != nil {
+		return nil, err
+	}
+	if mod.metrics != nil {
+		hits, misses, evictions := globalCacheInstance.metricsDelta(stats)
+		mod.metrics.emit(mod.vu, stats, hits, misses, evictions)
+	}
+	return map[string]interface{}{
+		"hits":      stats.Hits,
+		"misses":    stats.Misses,
+		"evictions": stats.Evictions,
+		"size":      stats.Size,
+		"bytes":     stats.Bytes,
+	}, nil
+}
+
+// GetOrLoad is the JS-facing wrapper around Cache.GetOrLoad. loaderFn must be a synchronous function: sobek
+// invokes it inline on the VU's single JS goroutine, and that same goroutine is the only thing that could ever
+// advance its own event loop and settle a pending Promise - so there is no safe way to await one here without
+// deadlocking the VU. If loaderFn is async, or simply returns a Promise, GetOrLoad returns an error rather
+// than silently caching that Promise as the loaded value.
+func (mod *module) GetOrLoad(id string, loaderFn sobek.Value, ttl int) (interface{}, error) {
+	call, ok := sobek.AssertFunction(loaderFn)
+	if !ok {
+		return nil, errors.New("getOrLoad: loaderFn must be a function")
+	}
+	return globalCacheInstance.GetOrLoad(id, func() (interface{}, error) {
+		result, err := call(sobek.Undefined())
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := result.Export().(*sobek.Promise); ok {
+			return nil, errors.New("getOrLoad: loaderFn must be synchronous; async functions and functions " +
+				"returning a Promise are not supported")
+		}
+		return result.Export(), nil
+	}, ttl)
+}
+
+// Set stores a value in the cache under the specified id with an optional expiration. value may be any JS
+// value - a string, number, boolean, array, or object - not just a string; every value, strings included, is
+// msgpack-encoded (see codec.go) so it can cross transparently to remote backends.
 // Parameters:
 // - id: Unique identifier for the cache entry.
 // - value: Value to store in the cache.
@@ -92,25 +400,39 @@ func (mod *module) Exports() modules.Exports {
 // Returns:
 // - A boolean indicating if the value was successfully set.
 // - An error if the cache is not initialized or another issue occurs.
-func (c *Cache) Set(id, value string, expiration ...int) (bool, error) {
+func (c *Cache) Set(id string, value interface{}, expiration ...int) (bool, error) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	if c.cache == nil {
+	if c.backend == nil {
 		return false, errors.New("cache not initialized: please call init() first")
 	}
 	var exp time.Duration
 	if len(expiration) > 0 {
 		exp = time.Duration(expiration[0]) * time.Second
-	} else {
-		exp = cache.DefaultExpiration
 	}
-	c.cache.Set(id, value, exp)
-	_, found := c.cache.Get(id)
+	return c.setLocked(id, value, exp)
+}
+
+// setLocked performs the actual encode-and-store for Set, SetMulti, and GetOrLoad. Callers must already hold
+// c.mutex and have checked c.backend != nil.
+func (c *Cache) setLocked(id string, value interface{}, expiration time.Duration) (bool, error) {
+	encoded, err := encodeValue(value)
+	if err != nil {
+		return false, err
+	}
+	if err := c.backend.Set(id, encoded, expiration); err != nil {
+		return false, err
+	}
+	// Read back through TTL rather than Get, so the check does not count as a hit in the backend's stats.
+	_, found, err := c.backend.TTL(id)
+	if err != nil {
+		return false, err
+	}
 	return found, nil
 }
 
-// Get retrieves a value from the cache by its id.
+// Get retrieves a value from the cache by its id, decoded back to its original JS type.
 // Parameters:
 // - id: Unique identifier of the cache entry.
 // Returns:
@@ -120,14 +442,72 @@ func (c *Cache) Get(id string) (interface{}, error) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	if c.cache == nil {
+	if c.backend == nil {
+		return nil, errors.New("cache not initialized: please call init() first")
+	}
+	return c.getLocked(id)
+}
+
+// getLocked performs the actual fetch-and-decode for Get and GetMulti. Callers must already hold c.mutex and
+// have checked c.backend != nil.
+func (c *Cache) getLocked(id string) (interface{}, error) {
+	raw, found, err := c.backend.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	return decodeValue(raw)
+}
+
+// GetMulti retrieves the values stored under each of ids in one call, a common k6 setup-phase pattern for
+// hydrating many keys at once. Each id is still fetched with its own Backend.Get call, so a remote backend
+// sees one round trip per key. Keys that are not found are omitted from the result rather than causing an error.
+// Parameters:
+// - ids: Unique identifiers of the cache entries to retrieve.
+// Returns:
+// - A map of id to decoded value for every id that was found.
+// - An error if the cache is not initialized or another issue occurs.
+func (c *Cache) GetMulti(ids []string) (map[string]interface{}, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.backend == nil {
 		return nil, errors.New("cache not initialized: please call init() first")
 	}
-	value, found := c.cache.Get(id)
-	if found {
-		return value.(string), nil
+	result := make(map[string]interface{}, len(ids))
+	for _, id := range ids {
+		value, err := c.getLocked(id)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			result[id] = value
+		}
+	}
+	return result, nil
+}
+
+// SetMulti stores every id/value pair in values in one call, each using the cache's default expiration. It is
+// the batch counterpart to GetMulti, for scripts that hydrate many keys at once.
+// Parameters:
+// - values: Map of id to value to store.
+// Returns:
+// - An error if the cache is not initialized or another issue occurs.
+func (c *Cache) SetMulti(values map[string]interface{}) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.backend == nil {
+		return errors.New("cache not initialized: please call init() first")
+	}
+	for id, value := range values {
+		if _, err := c.setLocked(id, value, 0); err != nil {
+			return err
+		}
 	}
-	return nil, nil
+	return nil
 }
 
 // Flush clears all items from the cache, effectively resetting it.
@@ -135,9 +515,25 @@ func (c *Cache) Flush() error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	if c.cache == nil {
+	if c.backend == nil {
 		return errors.New("cache not initialized: please call init() first")
 	}
-	c.cache.Flush()
-	return nil
+	return c.backend.Flush()
+}
+
+// Stats returns usage statistics for the current backend. Only backends that implement StatsProvider support
+// it - currently the bounded LRU used by the "memory" driver when maxEntries/maxBytes are set, and the
+// "layered" driver's local L1.
+func (c *Cache) Stats() (BackendStats, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.backend == nil {
+		return BackendStats{}, errors.New("cache not initialized: please call init() first")
+	}
+	provider, ok := c.backend.(StatsProvider)
+	if !ok {
+		return BackendStats{}, errors.New("memory: Stats() requires a bounded LRU backend (set maxEntries/maxBytes, or use the layered driver)")
+	}
+	return provider.Stats(), nil
 }
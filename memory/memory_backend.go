@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// memoryBackend is the default Backend, storing entries in an in-process github.com/patrickmn/go-cache instance.
+// It only shares state within a single process, so VUs in distributed k6 runs each see their own copy; the
+// redis and memcache drivers exist for cases that need state shared across runners.
+type memoryBackend struct {
+	cache *cache.Cache
+}
+
+// newMemoryBackendFromDurations builds a memoryBackend from a default expiration and cleanup interval, used by
+// both call forms of Init.
+func newMemoryBackendFromDurations(defaultExpiration, cleanupInterval time.Duration) *memoryBackend {
+	return &memoryBackend{cache: cache.New(defaultExpiration, cleanupInterval)}
+}
+
+func (b *memoryBackend) Get(key string) (interface{}, bool, error) {
+	value, found := b.cache.Get(key)
+	return value, found, nil
+}
+
+func (b *memoryBackend) Set(key string, value interface{}, expiration time.Duration) error {
+	if expiration == 0 {
+		expiration = cache.DefaultExpiration
+	}
+	b.cache.Set(key, value, expiration)
+	return nil
+}
+
+func (b *memoryBackend) Delete(key string) error {
+	b.cache.Delete(key)
+	return nil
+}
+
+func (b *memoryBackend) Flush() error {
+	b.cache.Flush()
+	return nil
+}
+
+func (b *memoryBackend) Increment(key string, delta int64) (int64, error) {
+	if _, found := b.cache.Get(key); !found {
+		b.cache.Set(key, delta, cache.DefaultExpiration)
+		return delta, nil
+	}
+	return b.cache.IncrementInt64(key, delta)
+}
+
+func (b *memoryBackend) TTL(key string) (time.Duration, bool, error) {
+	_, expiration, found := b.cache.GetWithExpiration(key)
+	if !found {
+		return 0, false, nil
+	}
+	if expiration.IsZero() {
+		return -1, true, nil
+	}
+	return time.Until(expiration), true, nil
+}
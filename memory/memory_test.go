@@ -9,7 +9,7 @@ func TestCacheInit(t *testing.T) {
 	cache := &Cache{}
 	cache.Init(5, 10)
 
-	if cache.cache == nil {
+	if cache.backend == nil {
 		t.Fatalf("expected cache to be initialized")
 	}
 }
@@ -82,3 +82,120 @@ func TestCacheFlush(t *testing.T) {
 		t.Fatalf("expected Get to return nil after flush, got %v", got)
 	}
 }
+
+func TestCacheInitWithOptionsObject(t *testing.T) {
+	cache := &Cache{}
+	err := cache.Init(map[string]interface{}{"driver": "memory", "defaultExpiration": 5, "cleanupInterval": 10})
+	if err != nil {
+		t.Fatalf("expected Init to succeed, got err: %v", err)
+	}
+	if cache.backend == nil {
+		t.Fatalf("expected cache to be initialized")
+	}
+}
+
+func TestCacheInitWithUnknownDriver(t *testing.T) {
+	cache := &Cache{}
+	err := cache.Init(map[string]interface{}{"driver": "dynamodb"})
+	if err == nil {
+		t.Fatalf("expected Init to fail for an unregistered driver")
+	}
+}
+
+func TestCacheSetAndGetTypedValue(t *testing.T) {
+	cache := &Cache{}
+	cache.Init(5, 10)
+
+	value := map[string]interface{}{"name": "alice", "tags": []interface{}{"a", "b"}}
+	if _, err := cache.Set("profile", value); err != nil {
+		t.Fatalf("expected Set to succeed, got err: %v", err)
+	}
+
+	got, err := cache.Get("profile")
+	if err != nil {
+		t.Fatalf("expected Get to succeed, got err: %v", err)
+	}
+	decoded, ok := got.(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected Get to return a map, got %T", got)
+	}
+	if decoded["name"] != "alice" {
+		t.Fatalf("expected name to round-trip as %q, got %v", "alice", decoded["name"])
+	}
+}
+
+func TestCacheInitWithMaxEntriesReportsStats(t *testing.T) {
+	cache := &Cache{}
+	err := cache.Init(map[string]interface{}{"maxEntries": 10})
+	if err != nil {
+		t.Fatalf("expected Init to succeed, got err: %v", err)
+	}
+
+	cache.Set("key", "value")
+	cache.Get("key")
+	cache.Get("missing")
+
+	stats, err := cache.Stats()
+	if err != nil {
+		t.Fatalf("expected Stats to succeed, got err: %v", err)
+	}
+	if stats.Hits != 1 || stats.Misses != 1 {
+		t.Fatalf("expected 1 hit and 1 miss, got %+v", stats)
+	}
+}
+
+func TestCacheMetricsDeltaAcrossMultipleVUInstances(t *testing.T) {
+	cache := &Cache{}
+
+	// Simulates two VU module instances (each with its own *cacheMetrics, see NewModuleInstance) that both
+	// call Stats() against this one shared Cache at different points in a load test - vu1 sees activity from
+	// before vu2 joins, so vu2's first delta must not re-report it.
+	vu1Hits, vu1Misses, vu1Evictions := cache.metricsDelta(BackendStats{Hits: 5, Misses: 2, Evictions: 1})
+	vu2Hits, vu2Misses, vu2Evictions := cache.metricsDelta(BackendStats{Hits: 5, Misses: 2, Evictions: 1})
+	if vu2Hits != 0 || vu2Misses != 0 || vu2Evictions != 0 {
+		t.Fatalf("expected vu2's first delta to be 0 since nothing changed since vu1's call, got hits=%d misses=%d evictions=%d",
+			vu2Hits, vu2Misses, vu2Evictions)
+	}
+
+	vu1Hits2, vu1Misses2, vu1Evictions2 := cache.metricsDelta(BackendStats{Hits: 9, Misses: 3, Evictions: 1})
+
+	totalHits := vu1Hits + vu2Hits + vu1Hits2
+	totalMisses := vu1Misses + vu2Misses + vu1Misses2
+	totalEvictions := vu1Evictions + vu2Evictions + vu1Evictions2
+	if totalHits != 9 || totalMisses != 3 || totalEvictions != 1 {
+		t.Fatalf("expected combined deltas to equal the final cumulative totals (9, 3, 1), got (%d, %d, %d)",
+			totalHits, totalMisses, totalEvictions)
+	}
+}
+
+func TestCacheStatsUnsupportedByDefaultDriver(t *testing.T) {
+	cache := &Cache{}
+	cache.Init(5, 10)
+
+	if _, err := cache.Stats(); err == nil {
+		t.Fatalf("expected Stats to fail for the unbounded default driver")
+	}
+}
+
+func TestCacheGetMultiAndSetMulti(t *testing.T) {
+	cache := &Cache{}
+	cache.Init(5, 10)
+
+	if err := cache.SetMulti(map[string]interface{}{"a": "1", "b": "2"}); err != nil {
+		t.Fatalf("expected SetMulti to succeed, got err: %v", err)
+	}
+
+	got, err := cache.GetMulti([]string{"a", "b", "missing"})
+	if err != nil {
+		t.Fatalf("expected GetMulti to succeed, got err: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected GetMulti to return 2 entries, got %d", len(got))
+	}
+	if got["a"] != "1" || got["b"] != "2" {
+		t.Fatalf("expected GetMulti to return a=1 b=2, got %v", got)
+	}
+	if _, found := got["missing"]; found {
+		t.Fatalf("expected GetMulti to omit missing keys")
+	}
+}
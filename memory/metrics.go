@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"time"
+
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/metrics"
+)
+
+// cacheMetrics are the k6 custom metrics emitted on every successful Stats() call, giving scripts visibility
+// into whether the cache is actually helping under load right alongside their HTTP timings in k6's own output.
+// Nothing is emitted for a backend without StatsProvider, such as the unbounded default "memory" driver.
+//
+// hits/misses/evictions are Counter metrics, which k6 sums across every sample pushed. Since BackendStats
+// reports cumulative totals, emit is handed pre-computed deltas (see Cache.metricsDelta) rather than the raw
+// totals, so repeated Stats() calls - from this VU or any other sharing the same Cache - don't inflate the
+// reported sum.
+type cacheMetrics struct {
+	hits      *metrics.Metric
+	misses    *metrics.Metric
+	evictions *metrics.Metric
+	size      *metrics.Metric
+	bytes     *metrics.Metric
+}
+
+// registerCacheMetrics registers this module's metrics with vu's registry. It must be called from the init
+// context (i.e. from NewModuleInstance), since modules.VU.InitEnv is only available there.
+func registerCacheMetrics(vu modules.VU) (*cacheMetrics, error) {
+	registry := vu.InitEnv().Registry
+
+	hits, err := registry.NewMetric("working_memory_cache_hits", metrics.Counter)
+	if err != nil {
+		return nil, err
+	}
+	misses, err := registry.NewMetric("working_memory_cache_misses", metrics.Counter)
+	if err != nil {
+		return nil, err
+	}
+	evictions, err := registry.NewMetric("working_memory_cache_evictions", metrics.Counter)
+	if err != nil {
+		return nil, err
+	}
+	size, err := registry.NewMetric("working_memory_cache_size", metrics.Gauge)
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := registry.NewMetric("working_memory_cache_bytes", metrics.Gauge)
+	if err != nil {
+		return nil, err
+	}
+	return &cacheMetrics{hits: hits, misses: misses, evictions: evictions, size: size, bytes: bytes}, nil
+}
+
+// emit pushes stats as samples on vu's metric output, tagged the same way any other k6 metric from this
+// VU/iteration would be. hits/misses/evictions must already be deltas (see Cache.metricsDelta), not raw
+// cumulative totals.
+func (m *cacheMetrics) emit(vu modules.VU, stats BackendStats, hits, misses, evictions uint64) {
+	state := vu.State()
+	if state == nil {
+		return
+	}
+	now := time.Now()
+	tags := state.Tags.GetCurrentValues().Tags
+
+	metrics.PushIfNotDone(vu.Context(), state.Samples, metrics.Samples([]metrics.Sample{
+		{TimeSeries: metrics.TimeSeries{Metric: m.hits, Tags: tags}, Time: now, Value: float64(hits)},
+		{TimeSeries: metrics.TimeSeries{Metric: m.misses, Tags: tags}, Time: now, Value: float64(misses)},
+		{TimeSeries: metrics.TimeSeries{Metric: m.evictions, Tags: tags}, Time: now, Value: float64(evictions)},
+		{TimeSeries: metrics.TimeSeries{Metric: m.size, Tags: tags}, Time: now, Value: float64(stats.Size)},
+		{TimeSeries: metrics.TimeSeries{Metric: m.bytes, Tags: tags}, Time: now, Value: float64(stats.Bytes)},
+	}))
+}
+
+// deltaUint64 returns current-last, or current if the backend's cumulative total has gone backwards
+// (e.g. a new Init replaced the backend with one whose counts start from zero), since a negative delta would
+// make no sense for a Counter metric.
+func deltaUint64(current, last uint64) uint64 {
+	if current < last {
+		return current
+	}
+	return current - last
+}
@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// PersistentCacheVersion salts the filenames InitPersistent derives from cache keys. Bump it to invalidate
+// every existing on-disk entry at once - e.g. after changing how a script generates the fixtures it caches -
+// without having to delete the directory by hand.
+var PersistentCacheVersion = 1
+
+// persistentBackend mirrors every Set, Delete and Flush made to an in-process Backend onto disk, under a
+// filename derived from a stable hash of the key, so cache contents survive between k6 runs. Increment passes
+// straight through to the in-process Backend and is not persisted. It stores each entry under
+// dir/hash[:2]/hash to keep any one directory from accumulating too many files.
+type persistentBackend struct {
+	Backend
+	dir string
+}
+
+// newPersistentBackend wraps local, mirroring every Set/Delete/Flush it receives onto files under dir.
+func newPersistentBackend(dir string, local Backend) (*persistentBackend, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &persistentBackend{Backend: local, dir: dir}, nil
+}
+
+func (b *persistentBackend) Set(key string, value interface{}, expiration time.Duration) error {
+	if err := b.Backend.Set(key, value, expiration); err != nil {
+		return err
+	}
+	return b.persist(key)
+}
+
+func (b *persistentBackend) Delete(key string) error {
+	if err := b.Backend.Delete(key); err != nil {
+		return err
+	}
+	if err := os.Remove(b.pathFor(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (b *persistentBackend) Flush() error {
+	if err := b.Backend.Flush(); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(b.dir); err != nil {
+		return err
+	}
+	return os.MkdirAll(b.dir, 0o755)
+}
+
+// persist writes key's current value and expiration to disk, reading both back from the in-process Backend so
+// it mirrors exactly what Set just stored there.
+func (b *persistentBackend) persist(key string) error {
+	raw, found, err := b.Backend.Get(key)
+	if err != nil || !found {
+		return err
+	}
+	payload, ok := rawToBytes(raw)
+	if !ok {
+		return fmt.Errorf("memory: cannot persist non-byte value for key %q", key)
+	}
+
+	ttl, _, err := b.Backend.TTL(key)
+	if err != nil {
+		return err
+	}
+	var expiresAt int64 // 0 means "never expires"
+	if ttl >= 0 {
+		expiresAt = time.Now().Add(ttl).UnixNano()
+	}
+
+	path := b.pathFor(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, encodeEntryFile(PersistentCacheVersion, key, expiresAt, payload), 0o644)
+}
+
+// pathFor derives the on-disk path for key from a hash salted with PersistentCacheVersion, splitting the
+// first two hex characters into a subdirectory so no single directory holds every entry.
+func (b *persistentBackend) pathFor(key string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s", PersistentCacheVersion, key)))
+	digest := hex.EncodeToString(sum[:])
+	return filepath.Join(b.dir, digest[:2], digest)
+}
+
+// loadFromDisk walks dir, restoring every entry whose stored expiration is still in the future into the
+// wrapped in-process Backend. It is called once, from Cache.InitPersistent.
+func (b *persistentBackend) loadFromDisk() error {
+	return filepath.WalkDir(b.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		return b.loadEntryFile(path)
+	})
+}
+
+func (b *persistentBackend) loadEntryFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	version, key, expiresAt, payload, err := decodeEntryFile(data)
+	if err != nil {
+		// Not a file we wrote (or it's corrupt); skip it rather than failing the whole load.
+		return nil
+	}
+	if version != PersistentCacheVersion {
+		// Written under a different PersistentCacheVersion; treat it as invalidated.
+		_ = os.Remove(path)
+		return nil
+	}
+
+	var expiration time.Duration
+	if expiresAt == 0 {
+		expiration = -1 // never expires, mirroring go-cache's NoExpiration sentinel
+	} else {
+		remaining := time.Until(time.Unix(0, expiresAt))
+		if remaining <= 0 {
+			_ = os.Remove(path)
+			return nil
+		}
+		expiration = remaining
+	}
+
+	return b.Backend.Set(key, payload, expiration)
+}
+
+// An entry file is [4-byte version][2-byte key length][key][8-byte expiresAt unix nanoseconds, 0 = never]
+// [payload]. version is stored alongside the hash-salted filename (rather than relying on the filename alone)
+// so a PersistentCacheVersion bump invalidates entries already on disk under the old version, not just new
+// writes.
+func encodeEntryFile(version int, key string, expiresAt int64, payload []byte) []byte {
+	buf := make([]byte, 4+2+len(key)+8+len(payload))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(version))
+	binary.BigEndian.PutUint16(buf[4:6], uint16(len(key)))
+	copy(buf[6:6+len(key)], key)
+	binary.BigEndian.PutUint64(buf[6+len(key):6+len(key)+8], uint64(expiresAt))
+	copy(buf[6+len(key)+8:], payload)
+	return buf
+}
+
+func decodeEntryFile(data []byte) (version int, key string, expiresAt int64, payload []byte, err error) {
+	if len(data) < 6 {
+		return 0, "", 0, nil, errors.New("memory: truncated cache entry file")
+	}
+	version = int(binary.BigEndian.Uint32(data[0:4]))
+	keyLen := int(binary.BigEndian.Uint16(data[4:6]))
+	if len(data) < 6+keyLen+8 {
+		return 0, "", 0, nil, errors.New("memory: truncated cache entry file")
+	}
+	key = string(data[6 : 6+keyLen])
+	expiresAt = int64(binary.BigEndian.Uint64(data[6+keyLen : 6+keyLen+8]))
+	payload = data[6+keyLen+8:]
+	return version, key, expiresAt, payload, nil
+}
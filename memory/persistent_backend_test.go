@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPersistentCacheSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	cache := &Cache{}
+	if err := cache.InitPersistent(dir, 5, 10); err != nil {
+		t.Fatalf("expected InitPersistent to succeed, got err: %v", err)
+	}
+	if _, err := cache.Set("token", "secret"); err != nil {
+		t.Fatalf("expected Set to succeed, got err: %v", err)
+	}
+
+	// Simulate a fresh k6 run against the same directory.
+	restarted := &Cache{}
+	if err := restarted.InitPersistent(dir, 5, 10); err != nil {
+		t.Fatalf("expected InitPersistent to succeed on restart, got err: %v", err)
+	}
+
+	got, err := restarted.Get("token")
+	if err != nil {
+		t.Fatalf("expected Get to succeed, got err: %v", err)
+	}
+	if got != "secret" {
+		t.Fatalf("expected the restarted cache to recover %q, got %v", "secret", got)
+	}
+}
+
+func TestPersistentCacheDoesNotReloadExpiredEntries(t *testing.T) {
+	dir := t.TempDir()
+
+	cache := &Cache{}
+	if err := cache.InitPersistent(dir, 5, 10); err != nil {
+		t.Fatalf("expected InitPersistent to succeed, got err: %v", err)
+	}
+	if _, err := cache.Set("short-lived", "value", 1); err != nil {
+		t.Fatalf("expected Set to succeed, got err: %v", err)
+	}
+
+	time.Sleep(2 * time.Second)
+
+	restarted := &Cache{}
+	if err := restarted.InitPersistent(dir, 5, 10); err != nil {
+		t.Fatalf("expected InitPersistent to succeed on restart, got err: %v", err)
+	}
+
+	got, err := restarted.Get("short-lived")
+	if err != nil {
+		t.Fatalf("expected Get to succeed, got err: %v", err)
+	}
+	if got != nil {
+		t.Fatalf("expected the expired entry not to be reloaded, got %v", got)
+	}
+}
+
+func TestPersistentCacheVersionBumpInvalidatesEntries(t *testing.T) {
+	dir := t.TempDir()
+	originalVersion := PersistentCacheVersion
+	defer func() { PersistentCacheVersion = originalVersion }()
+
+	cache := &Cache{}
+	if err := cache.InitPersistent(dir, 5, 10); err != nil {
+		t.Fatalf("expected InitPersistent to succeed, got err: %v", err)
+	}
+	if _, err := cache.Set("key", "value"); err != nil {
+		t.Fatalf("expected Set to succeed, got err: %v", err)
+	}
+
+	PersistentCacheVersion = originalVersion + 1
+
+	restarted := &Cache{}
+	if err := restarted.InitPersistent(dir, 5, 10); err != nil {
+		t.Fatalf("expected InitPersistent to succeed on restart, got err: %v", err)
+	}
+
+	got, err := restarted.Get("key")
+	if err != nil {
+		t.Fatalf("expected Get to succeed, got err: %v", err)
+	}
+	if got != nil {
+		t.Fatalf("expected a version bump to hide the old entry, got %v", got)
+	}
+}
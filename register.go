@@ -2,6 +2,8 @@ package memory
 
 import (
 	"github.com/gera-cl/xk6-working-memory/memory"
+	_ "github.com/gera-cl/xk6-working-memory/memory/memcache"
+	_ "github.com/gera-cl/xk6-working-memory/memory/redis"
 
 	"go.k6.io/k6/js/modules"
 )